@@ -0,0 +1,260 @@
+package lru
+
+import (
+	"container/list"
+	"time"
+)
+
+// Policy selects the eviction strategy a shard uses once it's full.
+type Policy int
+
+const (
+	// PolicyLRU evicts the least-recently-used entry. This is the default.
+	PolicyLRU Policy = iota
+	// PolicyARC uses an Adaptive Replacement Cache: it keeps a recency list
+	// (T1) and a frequency list (T2), and uses two ghost lists (B1/B2) of
+	// recently evicted keys to adapt the balance between them over time.
+	PolicyARC
+	// PolicySieve uses the SIEVE algorithm: a single FIFO list with one
+	// "visited" bit per entry. Hits just set the bit instead of moving the
+	// entry, so the common case is cheaper than PolicyLRU's moveToFront.
+	PolicySieve
+)
+
+// Stats reports cache effectiveness counters. See LRU.Stats.
+type Stats struct {
+	Hits, Misses, Evictions int64
+	P                       int
+}
+
+// arcState holds the bookkeeping an ARC shard needs beyond ordinary LRU: a
+// "frequent" list alongside the recency list, and two ghost lists that
+// remember the keys (but not the values) of recently evicted entries so a
+// miss can tell whether it's thrashing a recency- or frequency-favored key.
+type arcState[K comparable, V any] struct {
+	cap int
+	p   int // target size of T1; 0 <= p <= cap
+
+	t1, t2 *list.List // T1: seen once recently. T2: seen >= 2 times.
+	b1, b2 *list.List // ghost lists: keys only, no values
+
+	t1idx, t2idx map[K]*list.Element
+	b1idx, b2idx map[K]*list.Element
+}
+
+func newARCState[K comparable, V any](cap int) *arcState[K, V] {
+	return &arcState[K, V]{
+		cap:   cap,
+		t1:    list.New(),
+		t2:    list.New(),
+		b1:    list.New(),
+		b2:    list.New(),
+		t1idx: make(map[K]*list.Element),
+		t2idx: make(map[K]*list.Element),
+		b1idx: make(map[K]*list.Element),
+		b2idx: make(map[K]*list.Element),
+	}
+}
+
+// get looks up key in T1 or T2, promoting it to T2's MRU end on either hit.
+// If the entry had expired, it's evicted instead and reported via the
+// extra (evictedKey, evictedVal, expired) returns.
+func (a *arcState[K, V]) get(key K) (value V, ok bool, evictedKey K, evictedVal V, expired bool) {
+	if le, found := a.t1idx[key]; found {
+		e := le.Value.(*entry[K, V])
+		if e.expired() {
+			evictedKey, evictedVal = a.evictFromT1(le)
+			return value, false, evictedKey, evictedVal, true
+		}
+		a.t1.Remove(le)
+		delete(a.t1idx, key)
+		a.t2idx[key] = a.t2.PushFront(e)
+		return e.val, true, evictedKey, evictedVal, false
+	}
+	if le, found := a.t2idx[key]; found {
+		e := le.Value.(*entry[K, V])
+		if e.expired() {
+			evictedKey, evictedVal = a.evictFromT2(le)
+			return value, false, evictedKey, evictedVal, true
+		}
+		a.t2.MoveToFront(le)
+		return e.val, true, evictedKey, evictedVal, false
+	}
+	return value, false, evictedKey, evictedVal, false
+}
+
+// add inserts or updates key, adapting p when the key is found in a ghost
+// list, then evicts if T1+T2 now exceeds capacity.
+func (a *arcState[K, V]) add(key K, val V, expiry time.Time) (evictedKey K, evictedVal V, evicted bool) {
+	if le, found := a.t1idx[key]; found {
+		e := le.Value.(*entry[K, V])
+		e.val, e.expiry = val, expiry
+		a.t1.Remove(le)
+		delete(a.t1idx, key)
+		a.t2idx[key] = a.t2.PushFront(e)
+		return evictedKey, evictedVal, false
+	}
+	if le, found := a.t2idx[key]; found {
+		e := le.Value.(*entry[K, V])
+		e.val, e.expiry = val, expiry
+		a.t2.MoveToFront(le)
+		return evictedKey, evictedVal, false
+	}
+
+	fromB2 := false
+	wasGhost := false
+	if le, found := a.b1idx[key]; found {
+		a.p = min(a.cap, a.p+max(1, a.b2.Len()/max(1, a.b1.Len())))
+		a.b1.Remove(le)
+		delete(a.b1idx, key)
+		wasGhost = true
+	} else if le, found := a.b2idx[key]; found {
+		a.p = max(0, a.p-max(1, a.b1.Len()/max(1, a.b2.Len())))
+		a.b2.Remove(le)
+		delete(a.b2idx, key)
+		wasGhost, fromB2 = true, true
+	}
+
+	e := &entry[K, V]{key: key, val: val, expiry: expiry}
+	if wasGhost {
+		a.t2idx[key] = a.t2.PushFront(e)
+	} else {
+		a.t1idx[key] = a.t1.PushFront(e)
+	}
+
+	if a.cap > 0 && a.t1.Len()+a.t2.Len() > a.cap {
+		evictedKey, evictedVal = a.evict(fromB2)
+		return evictedKey, evictedVal, true
+	}
+	return evictedKey, evictedVal, false
+}
+
+// evict removes one entry from T1 or T2 per the ARC replacement rule and
+// pushes its key onto the matching ghost list.
+func (a *arcState[K, V]) evict(fromB2 bool) (key K, val V) {
+	if a.t1.Len() > a.p || (fromB2 && a.t1.Len() > 0) {
+		if le := a.t1.Back(); le != nil {
+			return a.evictFromT1(le)
+		}
+		return key, val
+	}
+	if le := a.t2.Back(); le != nil {
+		return a.evictFromT2(le)
+	}
+	return key, val
+}
+
+func (a *arcState[K, V]) evictFromT1(le *list.Element) (key K, val V) {
+	e := le.Value.(*entry[K, V])
+	a.t1.Remove(le)
+	delete(a.t1idx, e.key)
+	a.b1idx[e.key] = a.b1.PushFront(e.key)
+	a.trimGhost(a.b1, a.b1idx)
+	return e.key, e.val
+}
+
+func (a *arcState[K, V]) evictFromT2(le *list.Element) (key K, val V) {
+	e := le.Value.(*entry[K, V])
+	a.t2.Remove(le)
+	delete(a.t2idx, e.key)
+	a.b2idx[e.key] = a.b2.PushFront(e.key)
+	a.trimGhost(a.b2, a.b2idx)
+	return e.key, e.val
+}
+
+func (a *arcState[K, V]) trimGhost(b *list.List, idx map[K]*list.Element) {
+	for a.cap > 0 && b.Len() > a.cap {
+		le := b.Back()
+		b.Remove(le)
+		delete(idx, le.Value.(K))
+	}
+}
+
+func (a *arcState[K, V]) removeKey(key K) (val V, removed bool) {
+	if le, found := a.t1idx[key]; found {
+		e := le.Value.(*entry[K, V])
+		a.t1.Remove(le)
+		delete(a.t1idx, key)
+		return e.val, true
+	}
+	if le, found := a.t2idx[key]; found {
+		e := le.Value.(*entry[K, V])
+		a.t2.Remove(le)
+		delete(a.t2idx, key)
+		return e.val, true
+	}
+	return val, false
+}
+
+// front returns the most recently touched entry, preferring T2 (frequent)
+// over T1 (recent) since a true global MRU isn't tracked across the two.
+func (a *arcState[K, V]) front() (key K, val V, ok bool) {
+	if le := a.t2.Front(); le != nil {
+		e := le.Value.(*entry[K, V])
+		return e.key, e.val, true
+	}
+	if le := a.t1.Front(); le != nil {
+		e := le.Value.(*entry[K, V])
+		return e.key, e.val, true
+	}
+	return key, val, false
+}
+
+func (a *arcState[K, V]) forEach(fn func(k K, v V) bool) bool {
+	for _, l := range [2]*list.List{a.t2, a.t1} {
+		for le := l.Front(); le != nil; le = le.Next() {
+			e := le.Value.(*entry[K, V])
+			if !fn(e.key, e.val) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (a *arcState[K, V]) forEachReverse(fn func(k K, v V) bool) bool {
+	for _, l := range [2]*list.List{a.t1, a.t2} {
+		for le := l.Back(); le != nil; le = le.Prev() {
+			e := le.Value.(*entry[K, V])
+			if !fn(e.key, e.val) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (a *arcState[K, V]) reapExpired() []entry[K, V] {
+	var evicted []entry[K, V]
+	for _, l := range [2]*list.List{a.t1, a.t2} {
+		for le := l.Back(); le != nil; {
+			prev := le.Prev()
+			if le.Value.(*entry[K, V]).expired() {
+				var k K
+				var v V
+				if l == a.t1 {
+					k, v = a.evictFromT1(le)
+				} else {
+					k, v = a.evictFromT2(le)
+				}
+				evicted = append(evicted, entry[K, V]{key: k, val: v})
+			}
+			le = prev
+		}
+	}
+	return evicted
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,52 @@
+package lru
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestARCConcurrentAddGet drives concurrent Add/Get against a PolicyARC
+// cache; run with -race to catch locking regressions in arcState.
+func TestARCConcurrentAddGet(t *testing.T) {
+	l := New[int, int](withCapacity[int, int](64), withShards[int, int](4), WithPolicy[int, int](PolicyARC))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				k := g*500 + i
+				l.Add(k, k)
+				l.Get(k)
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+// TestARCConcurrentStats calls Stats concurrently with Add under a small
+// capacity, cycling keys in and out of the ghost lists to keep arcState.p
+// under active mutation; run with -race to catch Stats reading p unlocked.
+func TestARCConcurrentStats(t *testing.T) {
+	l := New[int, int](withCapacity[int, int](2), withShards[int, int](1), WithPolicy[int, int](PolicyARC))
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 2000; i++ {
+			l.Add(i%3, i)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 2000; i++ {
+			l.Stats()
+		}
+	}()
+
+	wg.Wait()
+}
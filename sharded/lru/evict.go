@@ -0,0 +1,33 @@
+package lru
+
+// EvictReason describes why an entry left the cache. It's passed to the
+// callback registered via WithOnEvict.
+type EvictReason int
+
+const (
+	// EvictReasonCapacity means the entry was evicted to make room for a
+	// new one.
+	EvictReasonCapacity EvictReason = iota
+	// EvictReasonExplicit means the entry was removed by a call to Remove.
+	EvictReasonExplicit
+	// EvictReasonExpired means the entry's TTL had elapsed.
+	EvictReasonExpired
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictReasonCapacity:
+		return "capacity"
+	case EvictReasonExplicit:
+		return "explicit"
+	case EvictReasonExpired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+// OnEvictFunc is called whenever an entry leaves the cache, whether to
+// capacity pressure, an explicit Remove, or TTL expiration. It always runs
+// outside the shard's lock, so it's safe for it to call back into the LRU.
+type OnEvictFunc[K comparable, V any] func(key K, val V, reason EvictReason)
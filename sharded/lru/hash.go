@@ -0,0 +1,54 @@
+package lru
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"hash/maphash"
+	"unsafe"
+)
+
+// Hasher computes a shard-selection hash for a key of type K. Callers with
+// keys that need a custom notion of equivalence for sharding (or that want
+// to avoid the cost of hashing a large key) can supply their own via
+// WithHasher; otherwise New falls back to defaultHasher.
+type Hasher[K comparable] func(key K) uint32
+
+// defaultHasher builds a Hasher[K] specialized to K's concrete type once,
+// at construction time, rather than re-dispatching on every call. Common
+// key types are read out of K's underlying bytes via unsafe.Pointer and
+// hashed directly with maphash, so the hot path never boxes the key into
+// an interface or allocates; anything else falls back to gob encoding.
+func defaultHasher[K comparable]() Hasher[K] {
+	seed := maphash.MakeSeed()
+
+	var zero K
+	switch any(zero).(type) {
+	case string:
+		return func(key K) uint32 {
+			s := *(*string)(unsafe.Pointer(&key))
+			return uint32(maphash.String(seed, s))
+		}
+	case int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, uintptr,
+		float32, float64, bool:
+		// size these off unsafe.Sizeof rather than a hardcoded width: int
+		// and uintptr are 4 bytes on 32-bit architectures, and a fixed
+		// [8]byte cast would read past the key into adjacent memory there.
+		return func(key K) uint32 {
+			b := unsafe.Slice((*byte)(unsafe.Pointer(&key)), unsafe.Sizeof(key))
+			return uint32(maphash.Bytes(seed, b))
+		}
+	default:
+		// the key is some other comparable type, so we're now grasping at
+		// straws here. This will be at least an order of magnitude slower
+		// than the cases above, and allocates.
+		return func(key K) uint32 {
+			var buf bytes.Buffer
+			if err := gob.NewEncoder(&buf).Encode(key); err != nil {
+				panic(fmt.Sprintf("lru: could not hash key of type %T: %v", key, err))
+			}
+			return uint32(maphash.Bytes(seed, buf.Bytes()))
+		}
+	}
+}
@@ -1,46 +1,65 @@
 package lru
 
-import (
-	"bytes"
-	"encoding/binary"
-	"encoding/gob"
-	"fmt"
-	"hash/fnv"
-	"strconv"
-)
+import "time"
 
-// LRU is a last-recently-used list of items
-type LRU struct {
-	cap     int // the max number of items to hold
-	nshards int // number of shards
-	shards  []*shard
+// LRU is a last-recently-used list of items, sharded across several
+// independently-locked shards and keyed by a comparable type K.
+type LRU[K comparable, V any] struct {
+	cap          int // the max number of items to hold
+	nshards      int // number of shards
+	hasher       Hasher[K]
+	defaultTTL   time.Duration // applied by Add when set; AddWithTTL overrides per-entry
+	reapInterval time.Duration // if set, each shard evicts expired entries on this interval
+	policy       Policy
+	onEvict      OnEvictFunc[K, V]
+	shards       []*shard[K, V]
 }
 
-type entry struct {
-	key, val interface{}
+// LRUAny is a compatibility shim for callers migrating from the old
+// interface{}-keyed LRU; it behaves exactly like LRU[any, any].
+type LRUAny = LRU[any, any]
+
+type entry[K comparable, V any] struct {
+	key    K
+	val    V
+	expiry time.Time // zero value means the entry never expires
+
+	// prev/next link this entry into its shard's intrusive list (PolicyLRU
+	// and PolicySieve only); ARC links entries through container/list
+	// instead.
+	prev, next *entry[K, V]
+
+	visited bool // PolicySieve only: set on Get, cleared when the hand passes it
+}
+
+func (e *entry[K, V]) expired() bool {
+	return !e.expiry.IsZero() && time.Now().After(e.expiry)
 }
 
 // New creates a new LRU with the provided capacity. If cap less than 1, then the LRU
 // grows indefinitely
-func New(opts ...option) *LRU {
-	l := &LRU{}
+func New[K comparable, V any](opts ...Option[K, V]) *LRU[K, V] {
+	l := &LRU[K, V]{}
 	for _, o := range opts {
 		o.apply(l)
 	}
 	if l.nshards < 1 {
 		l.nshards = 1
 	}
+	if l.hasher == nil {
+		l.hasher = defaultHasher[K]()
+	}
 
 	cap := l.cap / l.nshards
-	l.shards = make([]*shard, l.nshards)
+	l.shards = make([]*shard[K, V], l.nshards)
 	for i := 0; i < l.nshards; i++ {
-		l.shards[i] = newShard(cap)
+		l.shards[i] = newShard[K, V](cap, l.reapInterval, l.policy, l.onEvict)
 	}
 	return l
 }
 
 // Len returns the number of items currently in the LRU
-func (l *LRU) Len() int {
+func (l *LRU[K, V]) Len() int {
 	l.lazyInit()
 	var len int
 	for i := 0; i < l.nshards; i++ {
@@ -51,150 +70,111 @@ func (l *LRU) Len() int {
 
 // this initializes some fields at first use. Helpful to
 // allow us to use the empty value of LRU
-func (l *LRU) lazyInit() {
+func (l *LRU[K, V]) lazyInit() {
 	if l.shards == nil {
 		l.nshards = 1
-		l.shards = []*shard{newShard(l.cap)}
+		if l.hasher == nil {
+			l.hasher = defaultHasher[K]()
+		}
+		l.shards = []*shard[K, V]{newShard[K, V](l.cap, l.reapInterval, l.policy, l.onEvict)}
+	}
+}
+
+// Add will insert a new keyval pair to the LRU. If the LRU was built with
+// WithDefaultTTL, the entry expires after that duration; use AddWithTTL to
+// set a per-entry expiration instead.
+func (l *LRU[K, V]) Add(k K, v V) {
+	l.lazyInit()
+	var expiry time.Time
+	if l.defaultTTL > 0 {
+		expiry = time.Now().Add(l.defaultTTL)
 	}
+	l.shard(k).add(k, v, expiry)
 }
 
-// Add will insert a new keyval pair to the LRU
-func (l *LRU) Add(k, v interface{}) {
+// AddWithTTL will insert a new keyval pair to the LRU that expires after ttl
+// has elapsed. A non-positive ttl means the entry never expires.
+func (l *LRU[K, V]) AddWithTTL(k K, v V, ttl time.Duration) {
 	l.lazyInit()
-	l.shard(k).add(k, v)
+	var expiry time.Time
+	if ttl > 0 {
+		expiry = time.Now().Add(ttl)
+	}
+	l.shard(k).add(k, v, expiry)
+}
+
+// Close stops the background reapers started by WithReapInterval. It is a
+// no-op if the LRU wasn't configured with a reap interval.
+func (l *LRU[K, V]) Close() {
+	l.lazyInit()
+	for _, s := range l.shards {
+		s.stopReap()
+	}
 }
 
 // PeekFront will return the element at the front of the queue without modifying
 // it in anyway
-func (l *LRU) PeekFront() (key, val interface{}) {
+func (l *LRU[K, V]) PeekFront() (key K, val V) {
 	l.lazyInit()
-	return l.shard(1).front()
+	key, val, _ = l.shards[0].front()
+	return key, val
 }
 
 // Get will try to retrieve a value from the given key. The second return is
 // true if the key was found.
-func (l *LRU) Get(key interface{}) (value interface{}, ok bool) {
+func (l *LRU[K, V]) Get(key K) (value V, ok bool) {
+	l.lazyInit()
 	return l.shard(key).get(key)
 }
 
 // Remove will remove the given key from the LRU
-func (l *LRU) Remove(key interface{}) {
+func (l *LRU[K, V]) Remove(key K) {
+	l.lazyInit()
 	l.shard(key).removeKey(key)
 }
 
 // TraverseFunc is the function called for each element when
 // traversing an LRU
-type TraverseFunc func(key, val interface{}) bool
+type TraverseFunc[K comparable, V any] func(key K, val V) bool
 
 // Traverse will call fn for each element in the LRU, from most recently used to
 // least. If fn returns false, the traverse stops
-func (l *LRU) Traverse(fn TraverseFunc) {
-L:
+func (l *LRU[K, V]) Traverse(fn TraverseFunc[K, V]) {
 	for _, s := range l.shards {
-		le := s.l.Front()
-		for {
-			if le == nil {
-				break L
-			}
-
-			e := le.Value.(*entry)
-			if !fn(e.key, e.val) {
-				break L
-			}
-			le = le.Next()
+		if !s.forEach(fn) {
+			return
 		}
 	}
 }
 
 // TraverseReverse will call fn for each element in the LRU, from least recently used to
 // most. If fn returns false, the traverse stops
-func (l *LRU) TraverseReverse(fn TraverseFunc) {
-L:
+func (l *LRU[K, V]) TraverseReverse(fn TraverseFunc[K, V]) {
 	for _, s := range l.shards {
-		le := s.l.Back()
-		for {
-			if le == nil {
-				break L
-			}
-
-			e := le.Value.(*entry)
-			if !fn(e.key, e.val) {
-				break L
-			}
-			le = le.Prev()
+		if !s.forEachReverse(fn) {
+			return
 		}
 	}
 }
 
-type stringer interface {
-	String() string
-}
-
-type byter interface {
-	Bytes() []byte
-}
-
-func (l *LRU) shard(key interface{}) *shard {
-	h := fnv.New32a() // used to hash a byte array
-
-	// try to get a bytes representation of the key any way we can, in order
-	// from fastest to slowest
-	switch v := key.(type) {
-	case []byte:
-		h.Write(v)
-	case byter:
-		h.Write(v.Bytes())
-	case string:
-		h.Write([]byte(v))
-	case stringer:
-		h.Write([]byte(v.String()))
-	case int:
-		h.Write(intBytes(v))
-	case *int:
-		h.Write(intBytes(*v))
-	case *bool, bool, []bool, *int8, int8, []int8, *uint8,
-		uint8, *int16, int16, []int16, *uint16,
-		uint16, []uint16, *int32, int32, []int32, *uint32, uint32, []uint32,
-		*int64, int64, []int64, *uint64, uint64, []uint64:
-		h.Write(toBytes(v))
-	default:
-		// the user is using an unknown type as the key, so we're now grasping
-		// at straws here. This will be at least an order of magnitude slower
-		// then the options above.
-		var buf bytes.Buffer
-		enc := gob.NewEncoder(&buf)
-		err := enc.Encode(v)
-		if err != nil {
-			panic(fmt.Sprintf("could not encode type %T as bytes", key))
+// Stats reports hit/miss/eviction counters accumulated across all shards,
+// along with the ARC target size p of the first shard. P is only
+// meaningful when the LRU was built with WithPolicy(PolicyARC).
+func (l *LRU[K, V]) Stats() Stats {
+	l.lazyInit()
+	var st Stats
+	for i, s := range l.shards {
+		hits, misses, evictions := s.stats()
+		st.Hits += hits
+		st.Misses += misses
+		st.Evictions += evictions
+		if i == 0 && s.arc != nil {
+			st.P = s.arcP()
 		}
-		h.Write(buf.Bytes())
 	}
-
-	return l.shards[h.Sum32()&uint32(l.nshards-1)]
+	return st
 }
 
-func toBytes(v interface{}) []byte {
-	var buf bytes.Buffer
-	if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
-		panic(fmt.Sprintf("could not encode %v as bytes: %v", v, err))
-	}
-	return buf.Bytes()
-}
-
-var il = strconv.IntSize / 8
-
-// helper function to quickly turn an int into a byte slice
-func intBytes(i int) []byte {
-	b := make([]byte, il)
-	b[0] = byte(i)
-	b[1] = byte(i >> 8)
-	b[2] = byte(i >> 16)
-	b[3] = byte(i >> 24)
-	if il == 8 {
-		b[4] = byte(i >> 32)
-		b[5] = byte(i >> 40)
-		b[6] = byte(i >> 48)
-		b[7] = byte(i >> 56)
-	}
-	return b
+func (l *LRU[K, V]) shard(key K) *shard[K, V] {
+	return l.shards[l.hasher(key)&uint32(l.nshards-1)]
 }
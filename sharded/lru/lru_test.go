@@ -0,0 +1,36 @@
+package lru
+
+import "testing"
+
+// TestAddAtCapacityZeroAlloc guards the zero-allocation claim on the node
+// recycling path: once the cache is full, Add should reuse the evicted
+// node and the default hasher should hash without allocating, so steady
+// state Add shouldn't allocate at all.
+func TestAddAtCapacityZeroAlloc(t *testing.T) {
+	l := New[int, int](withCapacity[int, int](8), withShards[int, int](1))
+	for i := 0; i < 8; i++ {
+		l.Add(i, i)
+	}
+
+	n := 0
+	allocs := testing.AllocsPerRun(1000, func() {
+		l.Add(n, n)
+		n++
+	})
+	if allocs != 0 {
+		t.Errorf("Add at capacity: got %v allocs/op, want 0", allocs)
+	}
+}
+
+func BenchmarkAddAtCapacity(b *testing.B) {
+	l := New[int, int](withCapacity[int, int](1024), withShards[int, int](1))
+	for i := 0; i < 1024; i++ {
+		l.Add(i, i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Add(i, i)
+	}
+}
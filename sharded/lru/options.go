@@ -1,23 +1,69 @@
 package lru
 
-type option interface {
-	apply(*LRU)
+import "time"
+
+// Option configures an LRU at construction time. See New.
+type Option[K comparable, V any] interface {
+	apply(*LRU[K, V])
 }
 
-type optionFn func(*LRU)
+type optionFn[K comparable, V any] func(*LRU[K, V])
 
-func (f optionFn) apply(l *LRU) {
+func (f optionFn[K, V]) apply(l *LRU[K, V]) {
 	f(l)
 }
 
-func withCapacity(cap int) option {
-	return optionFn(func(l *LRU) {
+func withCapacity[K comparable, V any](cap int) Option[K, V] {
+	return optionFn[K, V](func(l *LRU[K, V]) {
 		l.cap = cap
 	})
 }
 
-func withShards(n int) option {
-	return optionFn(func(l *LRU) {
+func withShards[K comparable, V any](n int) Option[K, V] {
+	return optionFn[K, V](func(l *LRU[K, V]) {
 		l.nshards = n
 	})
 }
+
+// WithHasher overrides the default key hashing strategy used to pick a
+// shard for a given key. Most callers won't need this; it exists for keys
+// whose generic hashing would otherwise fall back to the slow encoding/gob
+// path, or that need a custom notion of equivalence for sharding.
+func WithHasher[K comparable, V any](h Hasher[K]) Option[K, V] {
+	return optionFn[K, V](func(l *LRU[K, V]) {
+		l.hasher = h
+	})
+}
+
+// WithDefaultTTL makes Add expire entries after d has elapsed. It has no
+// effect on AddWithTTL, which always uses the ttl passed to it.
+func WithDefaultTTL[K comparable, V any](d time.Duration) Option[K, V] {
+	return optionFn[K, V](func(l *LRU[K, V]) {
+		l.defaultTTL = d
+	})
+}
+
+// WithReapInterval starts a background goroutine per shard that evicts
+// expired entries every d, so that idle entries don't linger in memory
+// until something happens to touch them. Call Close to stop the reapers.
+func WithReapInterval[K comparable, V any](d time.Duration) Option[K, V] {
+	return optionFn[K, V](func(l *LRU[K, V]) {
+		l.reapInterval = d
+	})
+}
+
+// WithPolicy selects the eviction policy each shard uses once full. The
+// default, if this option isn't given, is PolicyLRU.
+func WithPolicy[K comparable, V any](p Policy) Option[K, V] {
+	return optionFn[K, V](func(l *LRU[K, V]) {
+		l.policy = p
+	})
+}
+
+// WithOnEvict registers a callback invoked whenever an entry leaves the
+// cache. See OnEvictFunc for when it runs.
+func WithOnEvict[K comparable, V any](fn OnEvictFunc[K, V]) Option[K, V] {
+	return optionFn[K, V](func(l *LRU[K, V]) {
+		l.onEvict = fn
+	})
+}
@@ -0,0 +1,36 @@
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCloseTwice exercises the fix for a panic on double-Close: Close used
+// to close(s.reapDone) unconditionally, which panics if called again.
+func TestCloseTwice(t *testing.T) {
+	l := New[int, int](WithReapInterval[int, int](time.Millisecond))
+	l.Add(1, 1)
+	l.Close()
+	l.Close()
+}
+
+// TestReapConcurrentAddGet runs the background reaper alongside concurrent
+// Add/Get with short TTLs, to catch locking regressions under -race.
+func TestReapConcurrentAddGet(t *testing.T) {
+	l := New[int, int](
+		withShards[int, int](4),
+		WithDefaultTTL[int, int](time.Millisecond),
+		WithReapInterval[int, int](time.Millisecond),
+	)
+	defer l.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 2000; i++ {
+			l.Add(i, i)
+			l.Get(i)
+		}
+	}()
+	<-done
+}
@@ -1,105 +1,383 @@
 package lru
 
 import (
-	"container/list"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // a shard of the list
-type shard struct {
+type shard[K comparable, V any] struct {
 	cap        int
 	len        int32
-	sync.Mutex                               // protects the idem and list
-	idx        map[interface{}]*list.Element // the index for our list
-	l          *list.List                    // the actual list holding the data
+	policy     Policy
+	sync.Mutex                    // protects everything below
+	idx        map[K]*entry[K, V] // the index for our list (PolicyLRU/PolicySieve only)
+	head, tail *entry[K, V]       // intrusive list: head is the most recently inserted, or under PolicyLRU touched (PolicyLRU/PolicySieve only)
+	hand       *entry[K, V]       // SIEVE's eviction hand (PolicySieve only)
+	arc        *arcState[K, V]    // ARC bookkeeping (PolicyARC only)
+
+	onEvict OnEvictFunc[K, V] // called outside the lock whenever an entry leaves
+
+	hits, misses, evictions int64 // updated atomically, not under the lock
+
+	reapDone chan struct{} // closed by stopReap to stop the reaper goroutine, if any
+	stopOnce sync.Once     // guards reapDone so stopReap is safe to call more than once
 }
 
-// newShard creates a new shard
-func newShard(cap int) *shard {
-	s := &shard{
-		cap: cap,
-		l:   list.New(),
-		idx: make(map[interface{}]*list.Element, cap+1),
+// newShard creates a new shard. If reapInterval is positive, a background
+// goroutine walks the shard on that interval evicting expired entries.
+func newShard[K comparable, V any](cap int, reapInterval time.Duration, policy Policy, onEvict OnEvictFunc[K, V]) *shard[K, V] {
+	s := &shard[K, V]{
+		cap:     cap,
+		policy:  policy,
+		onEvict: onEvict,
+	}
+	switch policy {
+	case PolicyARC:
+		s.arc = newARCState[K, V](cap)
+	default:
+		s.idx = make(map[K]*entry[K, V], cap+1)
+	}
+	if reapInterval > 0 {
+		s.reapDone = make(chan struct{})
+		go s.reapLoop(reapInterval)
 	}
 	return s
 }
 
+// reapLoop periodically evicts expired entries until stopReap is called.
+func (s *shard[K, V]) reapLoop(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			s.reapExpired()
+		case <-s.reapDone:
+			return
+		}
+	}
+}
+
+// reapExpired walks the shard evicting any entries that have expired.
+// Entries are ordered by use, not by expiry, so this keeps walking past
+// unexpired-but-old entries rather than stopping early.
+func (s *shard[K, V]) reapExpired() {
+	s.Lock()
+
+	var evicted []entry[K, V]
+	if s.policy == PolicyARC {
+		evicted = s.arc.reapExpired()
+	} else {
+		for e := s.tail; e != nil; {
+			prev := e.prev
+			if e.expired() {
+				k, v := s.removeNode(e)
+				evicted = append(evicted, entry[K, V]{key: k, val: v})
+			}
+			e = prev
+		}
+	}
+
+	s.Unlock()
+	for _, e := range evicted {
+		s.notifyEvict(e.key, e.val, EvictReasonExpired)
+	}
+}
+
+// stopReap stops this shard's reaper goroutine, if one was started. It's
+// safe to call more than once.
+func (s *shard[K, V]) stopReap() {
+	if s.reapDone == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.reapDone)
+	})
+}
+
 // Len returns the number of items currently in the LRU
-func (s *shard) Len() int { return int(atomic.LoadInt32(&s.len)) }
+func (s *shard[K, V]) Len() int {
+	if s.policy == PolicyARC {
+		s.Lock()
+		defer s.Unlock()
+		return s.arc.t1.Len() + s.arc.t2.Len()
+	}
+	return int(atomic.LoadInt32(&s.len))
+}
 
-// add will insert a new keyval pair to the shard
-func (s *shard) add(k, v interface{}) {
+// stats returns this shard's hit/miss/eviction counters
+func (s *shard[K, V]) stats() (hits, misses, evictions int64) {
+	return atomic.LoadInt64(&s.hits), atomic.LoadInt64(&s.misses), atomic.LoadInt64(&s.evictions)
+}
+
+// arcP returns the shard's current ARC target size p. It takes the lock
+// since arcState.add mutates p under s.Lock() inside add.
+func (s *shard[K, V]) arcP() int {
 	s.Lock()
 	defer s.Unlock()
+	return s.arc.p
+}
+
+// add will insert a new keyval pair to the shard. A zero expiry means the
+// entry never expires. At capacity, the evicted entry (the tail under
+// PolicyLRU, or the hand's victim under PolicySieve) is recycled for the
+// incoming key/value instead of allocating a new one.
+func (s *shard[K, V]) add(k K, v V, expiry time.Time) {
+	s.Lock()
+
+	if s.policy == PolicyARC {
+		evictedKey, evictedVal, evicted := s.arc.add(k, v, expiry)
+		s.Unlock()
+		if evicted {
+			atomic.AddInt64(&s.evictions, 1)
+			s.notifyEvict(evictedKey, evictedVal, EvictReasonCapacity)
+		}
+		return
+	}
 
 	// first let's see if we already have this key
-	if le, ok := s.idx[k]; ok {
-		// update the entry and move it to the front
-		le.Value.(*entry).val = v
-		s.l.MoveToFront(le)
+	if e, ok := s.idx[k]; ok {
+		e.val = v
+		e.expiry = expiry
+		if s.policy == PolicySieve {
+			e.visited = true
+		} else {
+			s.moveToFront(e)
+		}
+		s.Unlock()
 		return
 	}
-	s.idx[k] = s.l.PushFront(&entry{key: k, val: v})
-	atomic.AddInt32(&s.len, 1)
 
-	if s.cap > 0 && s.Len() > s.cap {
-		s.removeOldest()
+	var evictedKey K
+	var evictedVal V
+	var evicted bool
+	if s.cap > 0 && int(atomic.LoadInt32(&s.len)) >= s.cap {
+		// recycle the victim node for the new entry instead of allocating one
+		var victim *entry[K, V]
+		if s.policy == PolicySieve {
+			victim = s.sieveVictim()
+		} else {
+			victim = s.tail
+		}
+		evictedKey, evictedVal = victim.key, victim.val
+		s.unlink(victim)
+		delete(s.idx, evictedKey)
+
+		victim.key, victim.val, victim.expiry, victim.visited = k, v, expiry, false
+		s.idx[k] = victim
+		s.pushFront(victim)
+
+		evicted = true
+		atomic.AddInt64(&s.evictions, 1)
+	} else {
+		e := &entry[K, V]{key: k, val: v, expiry: expiry}
+		s.idx[k] = e
+		s.pushFront(e)
+		atomic.AddInt32(&s.len, 1)
+	}
+
+	s.Unlock()
+	if evicted {
+		s.notifyEvict(evictedKey, evictedVal, EvictReasonCapacity)
 	}
-	return
 }
 
 // front will return the element at the front of the queue without modifying
 // it in anyway
-func (s *shard) front() (key, val interface{}) {
+func (s *shard[K, V]) front() (key K, val V, ok bool) {
 	s.Lock()
 	defer s.Unlock()
 
-	if s.Len() == 0 {
-		return nil, nil
+	if s.policy == PolicyARC {
+		return s.arc.front()
 	}
 
-	le := s.l.Front()
-	return le.Value.(*entry).key, le.Value.(*entry).val
+	if s.head == nil {
+		return key, val, false
+	}
+	return s.head.key, s.head.val, true
 }
 
 // get will try to retrieve a value from the given key. The second return is
-// true if the key was found.
-func (s *shard) get(key interface{}) (value interface{}, ok bool) {
+// true if the key was found and not expired. An expired entry is removed
+// lazily and treated as a miss.
+func (s *shard[K, V]) get(key K) (value V, ok bool) {
 	s.Lock()
-	defer s.Unlock()
 
-	if le, found := s.idx[key]; found {
-		s.l.MoveToFront(le)
-		return le.Value.(*entry).val, true
+	if s.policy == PolicyARC {
+		var evictedKey K
+		var evictedVal V
+		var expired bool
+		value, ok, evictedKey, evictedVal, expired = s.arc.get(key)
+		s.Unlock()
+		s.recordHitMiss(ok)
+		if expired {
+			s.notifyEvict(evictedKey, evictedVal, EvictReasonExpired)
+		}
+		return value, ok
+	}
+
+	e, found := s.idx[key]
+	if !found {
+		s.Unlock()
+		atomic.AddInt64(&s.misses, 1)
+		return value, false
+	}
+
+	if e.expired() {
+		evictedKey, evictedVal := s.removeNode(e)
+		s.Unlock()
+		atomic.AddInt64(&s.misses, 1)
+		s.notifyEvict(evictedKey, evictedVal, EvictReasonExpired)
+		return value, false
+	}
+
+	if s.policy == PolicySieve {
+		e.visited = true
+	} else {
+		s.moveToFront(e)
 	}
-	return nil, false
+	val := e.val
+	s.Unlock()
+	atomic.AddInt64(&s.hits, 1)
+	return val, true
 }
 
-func (s *shard) removeOldest() (key, val interface{}) {
-	le := s.l.Back()
-	if le == nil {
-		return
+func (s *shard[K, V]) recordHitMiss(hit bool) {
+	if hit {
+		atomic.AddInt64(&s.hits, 1)
+	} else {
+		atomic.AddInt64(&s.misses, 1)
 	}
-	return s.removeElement(le)
 }
 
-func (s *shard) removeElement(le *list.Element) (key, val interface{}) {
-	e := le.Value.(*entry)
-	s.l.Remove(le)
-	delete(s.idx, e.key)
-	atomic.AddInt32(&s.len, -1)
-	return e.key, e.val
+func (s *shard[K, V]) notifyEvict(key K, val V, reason EvictReason) {
+	if s.onEvict != nil {
+		s.onEvict(key, val, reason)
+	}
 }
 
 // removeKey will remove the given key from the LRU
-func (s *shard) removeKey(key interface{}) {
+func (s *shard[K, V]) removeKey(key K) {
 	s.Lock()
-	defer s.Unlock()
 
-	le, ok := s.idx[key]
+	if s.policy == PolicyARC {
+		evictedVal, removed := s.arc.removeKey(key)
+		s.Unlock()
+		if removed {
+			s.notifyEvict(key, evictedVal, EvictReasonExplicit)
+		}
+		return
+	}
+
+	e, ok := s.idx[key]
 	if !ok {
+		s.Unlock()
+		return
+	}
+	evictedKey, evictedVal := s.removeNode(e)
+	s.Unlock()
+	s.notifyEvict(evictedKey, evictedVal, EvictReasonExplicit)
+}
+
+// forEach calls fn for each entry in the shard from most to least recently
+// used, stopping early if fn returns false. It reports whether the caller
+// should keep traversing subsequent shards.
+func (s *shard[K, V]) forEach(fn func(k K, v V) bool) bool {
+	if s.policy == PolicyARC {
+		return s.arc.forEach(fn)
+	}
+
+	for e := s.head; e != nil; e = e.next {
+		if !fn(e.key, e.val) {
+			return false
+		}
+	}
+	return true
+}
+
+// forEachReverse calls fn for each entry in the shard from least to most
+// recently used, stopping early if fn returns false.
+func (s *shard[K, V]) forEachReverse(fn func(k K, v V) bool) bool {
+	if s.policy == PolicyARC {
+		return s.arc.forEachReverse(fn)
+	}
+
+	for e := s.tail; e != nil; e = e.prev {
+		if !fn(e.key, e.val) {
+			return false
+		}
+	}
+	return true
+}
+
+// pushFront links e in as the new head of the intrusive LRU list.
+func (s *shard[K, V]) pushFront(e *entry[K, V]) {
+	e.prev = nil
+	e.next = s.head
+	if s.head != nil {
+		s.head.prev = e
+	} else {
+		s.tail = e
+	}
+	s.head = e
+}
+
+// moveToFront relinks an already-present entry to the head of the list.
+func (s *shard[K, V]) moveToFront(e *entry[K, V]) {
+	if s.head == e {
 		return
 	}
-	s.removeElement(le)
+	s.unlink(e)
+	s.pushFront(e)
+}
+
+// sieveVictim runs SIEVE's clock hand backward from s.hand (or s.tail, on
+// the first call) clearing visited bits until it finds an unvisited entry,
+// which becomes the victim. The hand is left just before the victim so the
+// next call resumes from there.
+func (s *shard[K, V]) sieveVictim() *entry[K, V] {
+	cur := s.hand
+	if cur == nil {
+		cur = s.tail
+	}
+	for {
+		if cur == nil {
+			cur = s.tail
+		}
+		if !cur.visited {
+			s.hand = cur.prev
+			return cur
+		}
+		cur.visited = false
+		cur = cur.prev
+	}
+}
+
+// unlink removes e from the intrusive LRU list without touching idx.
+func (s *shard[K, V]) unlink(e *entry[K, V]) {
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		s.head = e.next
+	}
+	if e.next != nil {
+		e.next.prev = e.prev
+	} else {
+		s.tail = e.prev
+	}
+	e.prev, e.next = nil, nil
+}
+
+// removeNode unlinks e from the list and idx, and reports its key/val.
+func (s *shard[K, V]) removeNode(e *entry[K, V]) (key K, val V) {
+	if s.hand == e {
+		s.hand = e.prev
+	}
+	s.unlink(e)
+	delete(s.idx, e.key)
+	atomic.AddInt32(&s.len, -1)
+	return e.key, e.val
 }
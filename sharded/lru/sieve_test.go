@@ -0,0 +1,27 @@
+package lru
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestSieveConcurrentAddGet drives concurrent Add/Get against a PolicySieve
+// cache; run with -race to catch locking regressions in the clock-hand
+// eviction path.
+func TestSieveConcurrentAddGet(t *testing.T) {
+	l := New[int, int](withCapacity[int, int](64), withShards[int, int](4), WithPolicy[int, int](PolicySieve))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				k := g*500 + i
+				l.Add(k, k)
+				l.Get(k)
+			}
+		}(g)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,93 @@
+package lru
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+)
+
+// snapshotVersion identifies the wire format written by Snapshot. It lets
+// Restore reject snapshots from an incompatible future version instead of
+// failing on a confusing gob decode error.
+const snapshotVersion = 1
+
+type snapshotHeader struct {
+	Version int
+	Cap     int
+	Shards  int
+}
+
+type snapshotEntry[K comparable, V any] struct {
+	Key K
+	Val V
+}
+
+// Snapshot writes every entry currently in the LRU to w using encoding/gob,
+// preceded by a header recording the capacity, shard count, and format
+// version. Entries are written shard by shard, most- to least-recently-used
+// within each shard. TTLs are not preserved; restored entries are inserted
+// as ordinary Adds.
+func (l *LRU[K, V]) Snapshot(w io.Writer) error {
+	l.lazyInit()
+
+	enc := gob.NewEncoder(w)
+	header := snapshotHeader{Version: snapshotVersion, Cap: l.cap, Shards: l.nshards}
+	if err := enc.Encode(header); err != nil {
+		return err
+	}
+
+	var entries []snapshotEntry[K, V]
+	for _, s := range l.shards {
+		entries = append(entries, s.snapshotEntries()...)
+	}
+	return enc.Encode(entries)
+}
+
+// snapshotEntries returns a copy of every (key, val) pair in the shard,
+// most- to least-recently-used, taking the shard lock for the duration of
+// the copy so it's safe to call while the shard is in active use.
+func (s *shard[K, V]) snapshotEntries() []snapshotEntry[K, V] {
+	s.Lock()
+	defer s.Unlock()
+
+	var entries []snapshotEntry[K, V]
+	if s.policy == PolicyARC {
+		s.arc.forEach(func(k K, v V) bool {
+			entries = append(entries, snapshotEntry[K, V]{Key: k, Val: v})
+			return true
+		})
+		return entries
+	}
+	for e := s.head; e != nil; e = e.next {
+		entries = append(entries, snapshotEntry[K, V]{Key: e.key, Val: e.val})
+	}
+	return entries
+}
+
+// Restore reads a snapshot written by Snapshot and adds its entries to l via
+// Add, re-hashing each key through l.shard so the shard count recorded in
+// the snapshot need not match l's own. It does not clear l first, so
+// restoring into a non-empty LRU merges the two; entries are added
+// oldest-first so the resulting recency order matches the snapshot.
+func (l *LRU[K, V]) Restore(r io.Reader) error {
+	l.lazyInit()
+
+	dec := gob.NewDecoder(r)
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return err
+	}
+	if header.Version != snapshotVersion {
+		return fmt.Errorf("lru: unsupported snapshot version %d", header.Version)
+	}
+
+	var entries []snapshotEntry[K, V]
+	if err := dec.Decode(&entries); err != nil {
+		return err
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		l.Add(entries[i].Key, entries[i].Val)
+	}
+	return nil
+}